@@ -38,6 +38,7 @@ require (
 	github.com/package-url/packageurl-go v0.1.1-0.20220203205134-d70459300c8a
 	github.com/pkg/errors v0.9.1
 	github.com/samber/lo v1.38.1
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.0
 	github.com/savioxavier/termlink v1.2.1
 	github.com/sigstore/cosign/v2 v2.0.3-0.20230425232139-17cc13812d8a
 	github.com/spf13/cobra v1.7.0
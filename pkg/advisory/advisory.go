@@ -155,7 +155,7 @@ func Discover(opts DiscoverOptions) error {
 
 	var apkindexes []*repository.ApkIndex
 	for _, arch := range opts.Arches {
-		apkindex, err := index.Index(arch, opts.PackageRepositoryURL)
+		apkindex, err := index.Index(ctx, arch, opts.PackageRepositoryURL)
 		if err != nil {
 			return fmt.Errorf("unable to get APKINDEX for arch %q: %w", arch, err)
 		}
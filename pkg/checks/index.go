@@ -0,0 +1,109 @@
+package checks
+
+import (
+	"fmt"
+	"sort"
+
+	"chainguard.dev/melange/pkg/build"
+	apkversion "github.com/knqyf263/go-apk-version"
+	"gitlab.alpinelinux.org/alpine/go/repository"
+)
+
+// An IndexChangeKind describes how a package's configured version relates to
+// the newest version of that package in an APKINDEX.
+type IndexChangeKind string
+
+const (
+	// IndexChangeNew means the package isn't in the index yet.
+	IndexChangeNew IndexChangeKind = "new"
+
+	// IndexChangeVersionBump means the configured version is newer than the
+	// indexed one.
+	IndexChangeVersionBump IndexChangeKind = "version-bump"
+
+	// IndexChangeEpochBump means the configured version matches the indexed one,
+	// but the configured epoch is newer.
+	IndexChangeEpochBump IndexChangeKind = "epoch-bump"
+
+	// IndexChangeDowngrade means the index already has a newer version of the
+	// package than the config does.
+	IndexChangeDowngrade IndexChangeKind = "downgrade"
+
+	// IndexChangeNone means the configured version is already indexed.
+	IndexChangeNone IndexChangeKind = "unchanged"
+)
+
+// IndexChange compares a package config to the contents of an APKINDEX.
+type IndexChange struct {
+	Package string          `json:"package"`
+	Kind    IndexChangeKind `json:"kind"`
+	Local   string          `json:"local"`
+	Indexed string          `json:"indexed,omitempty"`
+}
+
+// CompareToIndex compares the version and epoch of each config's main package
+// to the newest version of that package in the given index. The result is
+// sorted by package name.
+func CompareToIndex(cfgs []build.Configuration, idx *repository.ApkIndex) ([]IndexChange, error) {
+	latest := make(map[string]apkversion.Version)
+	for _, p := range idx.Packages {
+		v, err := apkversion.NewVersion(p.Version)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse indexed version %q of package %q: %w", p.Version, p.Name, err)
+		}
+		if cur, ok := latest[p.Name]; !ok || cur.LessThan(v) {
+			latest[p.Name] = v
+		}
+	}
+
+	changes := make([]IndexChange, 0, len(cfgs))
+	for i := range cfgs {
+		p := cfgs[i].Package
+		local := fmt.Sprintf("%s-r%d", p.Version, p.Epoch)
+
+		change := IndexChange{
+			Package: p.Name,
+			Local:   local,
+		}
+
+		indexed, ok := latest[p.Name]
+		if !ok {
+			change.Kind = IndexChangeNew
+			changes = append(changes, change)
+			continue
+		}
+		change.Indexed = string(indexed)
+
+		lv, err := apkversion.NewVersion(local)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse version %q of package %q: %w", local, p.Name, err)
+		}
+
+		switch {
+		case lv.Equal(indexed):
+			change.Kind = IndexChangeNone
+		case lv.LessThan(indexed):
+			change.Kind = IndexChangeDowngrade
+		default:
+			// Comparing against the indexed version without its epoch tells a
+			// version bump apart from an epoch bump.
+			base, err := apkversion.NewVersion(p.Version + "-r0")
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse version %q of package %q: %w", p.Version, p.Name, err)
+			}
+			if base.GreaterThan(indexed) {
+				change.Kind = IndexChangeVersionBump
+			} else {
+				change.Kind = IndexChangeEpochBump
+			}
+		}
+
+		changes = append(changes, change)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Package < changes[j].Package
+	})
+
+	return changes, nil
+}
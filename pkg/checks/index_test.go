@@ -0,0 +1,44 @@
+package checks
+
+import (
+	"testing"
+
+	"chainguard.dev/melange/pkg/build"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.alpinelinux.org/alpine/go/repository"
+)
+
+func TestCompareToIndex(t *testing.T) {
+	idx := &repository.ApkIndex{
+		Packages: []*repository.Package{
+			{Name: "bumped", Version: "1.0.0-r0"},
+			{Name: "bumped", Version: "1.1.0-r2"},
+			{Name: "epoch", Version: "2.0.0-r0"},
+			{Name: "same", Version: "3.0.0-r1"},
+			{Name: "behind", Version: "4.1.0-r0"},
+		},
+	}
+
+	cfg := func(name, version string, epoch uint64) build.Configuration {
+		return build.Configuration{Package: build.Package{Name: name, Version: version, Epoch: epoch}}
+	}
+	cfgs := []build.Configuration{
+		cfg("same", "3.0.0", 1),
+		cfg("bumped", "1.2.0", 0),
+		cfg("epoch", "2.0.0", 1),
+		cfg("behind", "4.0.0", 5),
+		cfg("brand-new", "0.1.0", 0),
+	}
+
+	changes, err := CompareToIndex(cfgs, idx)
+	require.NoError(t, err)
+
+	assert.Equal(t, []IndexChange{
+		{Package: "behind", Kind: IndexChangeDowngrade, Local: "4.0.0-r5", Indexed: "4.1.0-r0"},
+		{Package: "brand-new", Kind: IndexChangeNew, Local: "0.1.0-r0"},
+		{Package: "bumped", Kind: IndexChangeVersionBump, Local: "1.2.0-r0", Indexed: "1.1.0-r2"},
+		{Package: "epoch", Kind: IndexChangeEpochBump, Local: "2.0.0-r1", Indexed: "2.0.0-r0"},
+		{Package: "same", Kind: IndexChangeNone, Local: "3.0.0-r1", Indexed: "3.0.0-r1"},
+	}, changes)
+}
@@ -1,13 +1,19 @@
 package cli
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"path"
+	"path/filepath"
 	"runtime"
 	"sort"
 	"strings"
@@ -35,6 +41,7 @@ var repos = map[string]string{
 
 func Apk() *cobra.Command {
 	var arch, repo string
+	var fetch indexFetchFlags
 	cmd := &cobra.Command{
 		Use:  "apk",
 		Args: cobra.MaximumNArgs(1),
@@ -45,8 +52,13 @@ func Apk() *cobra.Command {
 			}
 
 			if len(args) == 0 {
+				opts, err := fetch.options()
+				if err != nil {
+					return err
+				}
+
 				// Get the index and present a searchable list to select.
-				idx, err := index.Index(arch, repo)
+				idx, err := index.Index(cmd.Context(), arch, repo, opts...)
 				if err != nil {
 					return err
 				}
@@ -72,8 +84,18 @@ func Apk() *cobra.Command {
 				args[0] += ".apk"
 			}
 
+			header, err := parseHTTPHeaders(fetch.headers)
+			if err != nil {
+				return err
+			}
+
 			url := fmt.Sprintf("%s/%s/%s", repo, arch, args[0])
-			resp, err := http.Get(url) //nolint:gosec
+			req, err := http.NewRequestWithContext(cmd.Context(), http.MethodGet, url, http.NoBody)
+			if err != nil {
+				return err
+			}
+			req.Header = header
+			resp, err := http.DefaultClient.Do(req)
 			if err != nil {
 				return err
 			}
@@ -97,11 +119,13 @@ func Apk() *cobra.Command {
 	}
 	cmd.Flags().StringVar(&arch, "arch", "x86_64", "arch of package to get")
 	cmd.Flags().StringVar(&repo, "repo", "wolfi", "repo to get packages from")
+	fetch.addFlags(cmd)
 	return cmd
 }
 
 func Index() *cobra.Command {
-	var arch, repo string
+	var arch, repo, indexName string
+	var fetch indexFetchFlags
 	cmd := &cobra.Command{
 		Use:  "index",
 		Args: cobra.NoArgs,
@@ -111,7 +135,13 @@ func Index() *cobra.Command {
 				repo = got
 			}
 
-			idx, err := index.Index(arch, repo)
+			opts, err := fetch.options()
+			if err != nil {
+				return err
+			}
+
+			opts = append(opts, index.WithIndexFile(indexName))
+			idx, err := index.Index(cmd.Context(), arch, repo, opts...)
 			if err != nil {
 				return err
 			}
@@ -123,9 +153,144 @@ func Index() *cobra.Command {
 	}
 	cmd.Flags().StringVar(&arch, "arch", "x86_64", "arch of package to get")
 	cmd.Flags().StringVar(&repo, "repo", "wolfi", "repo to get packages from")
+	fetch.addFlags(cmd)
+	cmd.Flags().StringVar(&indexName, "index-name", "APKINDEX.tar.gz", "file name of the index within the arch directory")
+
+	cmd.AddCommand(IndexSign())
+
 	return cmd
 }
 
+// indexFetchFlags are the flags shared by commands that fetch an APKINDEX.
+type indexFetchFlags struct {
+	headers []string
+	retries int
+}
+
+func (f *indexFetchFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&f.headers, "http-header", nil, "HTTP header to send with requests, as 'Name: Value' (can be repeated)")
+	cmd.Flags().IntVar(&f.retries, "index-fetch-retries", 3, "number of times to retry fetching a remote APKINDEX after a network error, 429 or 5xx response")
+}
+
+// options returns the index options for the flags.
+func (f *indexFetchFlags) options() ([]index.Option, error) {
+	header, err := parseHTTPHeaders(f.headers)
+	if err != nil {
+		return nil, err
+	}
+	return []index.Option{index.WithHTTPHeader(header), index.WithRetries(f.retries)}, nil
+}
+
+// parseHTTPHeaders parses headers given on the command line as "Name: Value".
+func parseHTTPHeaders(headers []string) (http.Header, error) {
+	header := make(http.Header)
+	for _, h := range headers {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid HTTP header %q, expected 'Name: Value'", h)
+		}
+		header.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	return header, nil
+}
+
+func IndexSign() *cobra.Command {
+	var signingKey string
+	cmd := &cobra.Command{
+		Use:   "sign <path>",
+		Short: "Re-sign an existing APKINDEX with a new key",
+		Long: `This command replaces the signature of an existing APKINDEX.tar.gz without
+touching the packages it lists, e.g. after rotating the signing key.
+
+The existing signature (if any) is dropped, and the index is signed with the key
+passed via --signing-key. The index file is replaced in place.
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			indexFile := args[0]
+
+			if err := validateSigningKey(signingKey); err != nil {
+				return err
+			}
+
+			fi, err := os.Stat(indexFile)
+			if err != nil {
+				return err
+			}
+			b, err := os.ReadFile(indexFile)
+			if err != nil {
+				return err
+			}
+
+			// Only the signature is dropped; the index itself is kept byte
+			// for byte, including anything we don't model.
+			b, err = stripIndexSignature(b)
+			if err != nil {
+				return fmt.Errorf("error reading index %s: %w", indexFile, err)
+			}
+
+			// Write the unsigned index next to the original, so that the
+			// final rename doesn't cross filesystems.
+			tmp, err := os.CreateTemp(filepath.Dir(indexFile), ".APKINDEX-*.tar.gz")
+			if err != nil {
+				return err
+			}
+			defer os.Remove(tmp.Name())
+			if err := tmp.Chmod(fi.Mode()); err != nil {
+				tmp.Close()
+				return err
+			}
+			if _, err := tmp.Write(b); err != nil {
+				tmp.Close()
+				return err
+			}
+			if err := tmp.Close(); err != nil {
+				return err
+			}
+
+			log.Printf("signing index with %s", signingKey)
+			if err := melange.SignIndexCmd(ctx, signingKey, tmp.Name()); err != nil {
+				return fmt.Errorf("error signing index: %w", err)
+			}
+
+			return os.Rename(tmp.Name(), indexFile)
+		},
+	}
+	cmd.Flags().StringVar(&signingKey, "signing-key", "", "key to use to sign the index")
+	cmd.MarkFlagRequired("signing-key") //nolint:errcheck
+	return cmd
+}
+
+// stripIndexSignature returns the index without its signature. A signed
+// APKINDEX.tar.gz is the signature's gzip stream followed by the index's, so
+// everything from the first stream that isn't a signature on is kept as is.
+func stripIndexSignature(b []byte) ([]byte, error) {
+	r := bytes.NewReader(b)
+	for {
+		offset := len(b) - r.Len()
+
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		gz.Multistream(false)
+
+		hdr, err := tar.NewReader(gz).Next()
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(hdr.Name, ".SIGN.") {
+			return b[offset:], nil
+		}
+
+		// Reading the stream to its end leaves r at the start of the next one.
+		if _, err := io.Copy(io.Discard, gz); err != nil {
+			return nil, err
+		}
+	}
+}
+
 var docStyle = lipgloss.NewStyle().Margin(1, 2)
 
 type item struct {
@@ -170,8 +335,59 @@ var buckets = map[string]string{
 	"stage3": "gs://wolfi-production-registry-destination/bootstrap/stage3",
 }
 
+// validateSigningKey checks that the key at path can be used by melange to sign
+// an index: an unencrypted, PEM-encoded PKCS #1 RSA private key. If the public
+// half is next to it at <path>.pub, as written by "melange keygen", it must
+// match the private key.
+func validateSigningKey(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read signing key: %w", err)
+	}
+
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return fmt.Errorf("signing key %q is not PEM encoded", path)
+	}
+	if x509.IsEncryptedPEMBlock(block) { //nolint:staticcheck
+		return fmt.Errorf("signing key %q is encrypted, which isn't supported", path)
+	}
+
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("unable to parse signing key %q as an RSA private key: %w", path, err)
+	}
+	if err := key.Validate(); err != nil {
+		return fmt.Errorf("invalid signing key %q: %w", path, err)
+	}
+
+	pubPath := path + ".pub"
+	b, err = os.ReadFile(pubPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read public key: %w", err)
+	}
+
+	block, _ = pem.Decode(b)
+	if block == nil {
+		return fmt.Errorf("public key %q is not PEM encoded", pubPath)
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("unable to parse public key %q: %w", pubPath, err)
+	}
+	if !key.PublicKey.Equal(pub) {
+		return fmt.Errorf("public key %q doesn't match signing key %q", pubPath, path)
+	}
+
+	return nil
+}
+
 func GenerateIndex() *cobra.Command {
-	var arch, bucket, signingKey string
+	var arch, bucket, signingKey, indexName string
+	var includeSuffixes, excludeSuffixes []string
 	var publish bool
 	cmd := &cobra.Command{
 		Use: "generate-index",
@@ -183,8 +399,13 @@ Otherwise, specify any GCS bucket location with the gs:// prefix.
 
 If --signing-key is passed, the APKINDEX will be signed with that key.
 
-If --publish is passed, the APKINDEX will be published back to the bucket.
-Otherwise it's written to APKINDEX.tar.gz.
+If --publish is passed, the APKINDEX will be published back to the bucket,
+within the arch directory. Otherwise it's written to the current directory.
+Either way, it's named APKINDEX.tar.gz unless --index-name is passed.
+
+Packages whose names end with an --exclude-suffix (e.g. "-doc") are left out of
+the APKINDEX, unless their names also end with an --include-suffix. The APKs
+themselves are left in the bucket.
 `,
 		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -196,13 +417,15 @@ Otherwise it's written to APKINDEX.tar.gz.
 			if !strings.HasPrefix(bucket, "gs://") {
 				return errors.New("--bucket must have gs:// prefix")
 			}
+			if indexName == "" || strings.Contains(indexName, "/") {
+				return errors.New("--index-name must be a file name, without a directory")
+			}
 
+			// Check the key up front, rather than after fetching every APK.
 			if signingKey != "" {
-				k, err := os.Open(signingKey)
-				if err != nil {
+				if err := validateSigningKey(signingKey); err != nil {
 					return err
 				}
-				defer k.Close()
 			}
 
 			if publish && signingKey == "" {
@@ -234,6 +457,10 @@ Otherwise it's written to APKINDEX.tar.gz.
 				if !strings.HasSuffix(attrs.Name, ".apk") {
 					continue
 				}
+				if name := apkPackageName(attrs.Name); hasAnySuffix(name, excludeSuffixes) && !hasAnySuffix(name, includeSuffixes) {
+					log.Println("- skipping", attrs.Name)
+					continue
+				}
 
 				log.Println("-", attrs.Name)
 
@@ -257,11 +484,17 @@ Otherwise it's written to APKINDEX.tar.gz.
 			if err := errg.Wait(); err != nil {
 				return err
 			}
+			// Packages are fetched concurrently, so sort them into a total order
+			// to make the index reproducible for the same set of APKs.
 			sort.Slice(idx.Packages, func(i, j int) bool {
-				if idx.Packages[i].Name == idx.Packages[j].Name {
-					return idx.Packages[i].Version < idx.Packages[j].Version
+				a, b := idx.Packages[i], idx.Packages[j]
+				if a.Name != b.Name {
+					return a.Name < b.Name
 				}
-				return idx.Packages[i].Name < idx.Packages[j].Name
+				if a.Version != b.Version {
+					return a.Version < b.Version
+				}
+				return bytes.Compare(a.Checksum, b.Checksum) < 0
 			})
 
 			r, err := repository.ArchiveFromIndex(idx)
@@ -300,7 +533,7 @@ Otherwise it's written to APKINDEX.tar.gz.
 
 			if publish {
 				log.Println("publishing APKINDEX to repo")
-				w := client.Bucket(bkt).Object(path.Join(prefix, arch, "APKINDEX.tar.gz")).NewWriter(ctx)
+				w := client.Bucket(bkt).Object(path.Join(prefix, arch, indexName)).NewWriter(ctx)
 				w.CacheControl = "no-cache"
 				defer func() {
 					// Closing the GCS object also flushes remaining data, and so it can fail.
@@ -312,8 +545,8 @@ Otherwise it's written to APKINDEX.tar.gz.
 					return err
 				}
 			} else {
-				log.Println("writing APKINDEX.tar.gz")
-				i, err := os.Create("APKINDEX.tar.gz")
+				log.Println("writing", indexName)
+				i, err := os.Create(indexName)
 				if err != nil {
 					return err
 				}
@@ -331,5 +564,29 @@ Otherwise it's written to APKINDEX.tar.gz.
 	cmd.Flags().StringVar(&bucket, "bucket", "wolfi", "bucket to get packages from")
 	cmd.Flags().BoolVar(&publish, "publish", false, "if true, publish APKINDEX.tar.gz back to the repo (must be signed)")
 	cmd.Flags().StringVar(&signingKey, "signing-key", "", "if set, key to use to sign the index")
+	cmd.Flags().StringVar(&indexName, "index-name", "APKINDEX.tar.gz", "file name of the index")
+	cmd.Flags().StringArrayVar(&excludeSuffixes, "exclude-suffix", nil, "leave out packages whose names end with this suffix (can be repeated)")
+	cmd.Flags().StringArrayVar(&includeSuffixes, "include-suffix", nil, "keep packages whose names end with this suffix, even if excluded (can be repeated)")
 	return cmd
 }
+
+// apkPackageName returns the package name from the name of an APK object,
+// which is <name>-<version>-r<epoch>.apk.
+func apkPackageName(objectName string) string {
+	name := strings.TrimSuffix(path.Base(objectName), ".apk")
+	for i := 0; i < 2; i++ {
+		if idx := strings.LastIndex(name, "-"); idx > 0 {
+			name = name[:idx]
+		}
+	}
+	return name
+}
+
+func hasAnySuffix(s string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	melange "chainguard.dev/melange/pkg/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.alpinelinux.org/alpine/go/repository"
+)
+
+// writeTestKey writes a new PEM-encoded PKCS #1 RSA private key to dir/name.
+func writeTestKey(t *testing.T, dir, name string) string {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p := filepath.Join(dir, name)
+	b := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	require.NoError(t, os.WriteFile(p, b, 0o600))
+	return p
+}
+
+func TestValidateSigningKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	pkcs1 := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	pkcs8Bytes, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	pkcs8 := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8Bytes})
+	encryptedBlock, err := x509.EncryptPEMBlock(rand.Reader, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key), []byte("secret"), x509.PEMCipherAES256) //nolint:staticcheck
+	require.NoError(t, err)
+	encrypted := pem.EncodeToMemory(encryptedBlock)
+
+	publicKey := func(k *rsa.PrivateKey) []byte {
+		b, err := x509.MarshalPKIXPublicKey(&k.PublicKey)
+		require.NoError(t, err)
+		return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: b})
+	}
+
+	for _, tt := range []struct {
+		name    string
+		key     []byte
+		pub     []byte
+		wantErr string
+	}{
+		{name: "missing", wantErr: "unable to read signing key"},
+		{name: "not PEM", key: []byte("not a key"), wantErr: "is not PEM encoded"},
+		{name: "encrypted", key: encrypted, wantErr: "is encrypted"},
+		{name: "PKCS #8", key: pkcs8, wantErr: "unable to parse signing key"},
+		{name: "valid", key: pkcs1},
+		{name: "valid with public key", key: pkcs1, pub: publicKey(key)},
+		{name: "public key not PEM", key: pkcs1, pub: []byte("not a key"), wantErr: "is not PEM encoded"},
+		{name: "public key mismatch", key: pkcs1, pub: publicKey(otherKey), wantErr: "doesn't match signing key"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			p := filepath.Join(t.TempDir(), "test.rsa")
+			if tt.key != nil {
+				require.NoError(t, os.WriteFile(p, tt.key, 0o600))
+			}
+			if tt.pub != nil {
+				require.NoError(t, os.WriteFile(p+".pub", tt.pub, 0o644))
+			}
+
+			err := validateSigningKey(p)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+// signatureEntries returns the names of the signature entries in the index.
+func signatureEntries(t *testing.T, indexFile string) []string {
+	t.Helper()
+
+	f, err := os.Open(indexFile)
+	require.NoError(t, err)
+	defer f.Close()
+
+	// A signed index is the signature's tar stream followed by the index's, each
+	// gzipped separately, so read it as a single multistream archive.
+	gz, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+
+	var sigs []string
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		if strings.HasPrefix(hdr.Name, ".SIGN.") {
+			sigs = append(sigs, hdr.Name)
+		}
+	}
+	return sigs
+}
+
+func TestIndexSign(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	oldKey := writeTestKey(t, dir, "old.rsa")
+	newKey := writeTestKey(t, dir, "new.rsa")
+
+	want := &repository.ApkIndex{
+		Description: "wolfi-test",
+		Packages: []*repository.Package{
+			{Name: "hello", Version: "1.0.0-r0", Arch: "x86_64"},
+			{Name: "hello", Version: "1.1.0-r0", Arch: "x86_64"},
+			{Name: "world", Version: "2.0.0-r1", Arch: "x86_64", Dependencies: []string{"hello"}},
+		},
+	}
+
+	// Start from an index signed with the old key.
+	indexFile := filepath.Join(dir, "APKINDEX.tar.gz")
+	r, err := repository.ArchiveFromIndex(want)
+	require.NoError(t, err)
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(indexFile, b, 0o644))
+	require.NoError(t, melange.SignIndexCmd(ctx, oldKey, indexFile))
+	require.Equal(t, []string{".SIGN.RSA.old.rsa.pub"}, signatureEntries(t, indexFile))
+
+	signed, err := os.ReadFile(indexFile)
+	require.NoError(t, err)
+	before, err := stripIndexSignature(signed)
+	require.NoError(t, err)
+	require.Equal(t, b, before)
+
+	cmd := IndexSign()
+	cmd.SetArgs([]string{indexFile, "--signing-key", newKey})
+	require.NoError(t, cmd.ExecuteContext(ctx))
+
+	assert.Equal(t, []string{".SIGN.RSA.new.rsa.pub"}, signatureEntries(t, indexFile))
+
+	// The index itself is carried over unchanged.
+	resigned, err := os.ReadFile(indexFile)
+	require.NoError(t, err)
+	after, err := stripIndexSignature(resigned)
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+
+	f, err := os.Open(indexFile)
+	require.NoError(t, err)
+	defer f.Close()
+	got, err := repository.IndexFromArchive(f)
+	require.NoError(t, err)
+
+	assert.Equal(t, want.Description, got.Description)
+	require.Len(t, got.Packages, len(want.Packages))
+	for i, p := range want.Packages {
+		assert.Equal(t, p.Name, got.Packages[i].Name)
+		assert.Equal(t, p.Version, got.Packages[i].Version)
+		assert.Equal(t, p.Arch, got.Packages[i].Arch)
+		assert.Equal(t, p.Dependencies, got.Packages[i].Dependencies)
+	}
+}
+
+func TestAPKPackageName(t *testing.T) {
+	for _, tt := range []struct {
+		objectName string
+		want       string
+	}{
+		{objectName: "os/x86_64/hello-2.12-r1.apk", want: "hello"},
+		{objectName: "os/x86_64/hello-doc-2.12-r1.apk", want: "hello-doc"},
+		{objectName: "py3.11-setuptools-67.7.2-r0.apk", want: "py3.11-setuptools"},
+	} {
+		t.Run(tt.objectName, func(t *testing.T) {
+			assert.Equal(t, tt.want, apkPackageName(tt.objectName))
+		})
+	}
+}
@@ -14,7 +14,9 @@ func Check() *cobra.Command {
 	}
 	cmd.AddCommand(
 		Diff(),
+		CheckDowngrade(),
 		CheckUpdate(),
+		CheckPublish(),
 		SoName(),
 	)
 	return cmd
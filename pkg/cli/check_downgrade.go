@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/checks"
+)
+
+func CheckDowngrade() *cobra.Command {
+	var arch, repo string
+	var fetch indexFetchFlags
+	cmd := &cobra.Command{
+		Use:           "downgrade [configs...]",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Short:         "Check that no config is behind the version in a package repository",
+		Long: `Check that no config is behind the version in a package repository.
+
+Each config's version and epoch is compared to the newest version of the
+package in the repository's APKINDEX. If the repository already has a newer
+version, building the config would produce an older package, so the check
+fails. Configs that don't target the given architecture are ignored.
+
+If no configs are given, all configs in the current directory are checked.
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := fetch.options()
+			if err != nil {
+				return err
+			}
+
+			changes, err := indexChanges(cmd.Context(), args, arch, repo, opts...)
+			if err != nil {
+				return err
+			}
+
+			downgrades := 0
+			for _, c := range changes {
+				if c.Kind != checks.IndexChangeDowngrade {
+					continue
+				}
+				downgrades++
+				fmt.Printf("%s: config version %s is behind indexed version %s\n", c.Package, c.Local, c.Indexed)
+			}
+
+			if downgrades > 0 {
+				return fmt.Errorf("found %d config(s) behind the index", downgrades)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&arch, "arch", "x86_64", "arch of the APKINDEX to compare against")
+	cmd.Flags().StringVar(&repo, "repo", "wolfi", "repo to compare against")
+	fetch.addFlags(cmd)
+	return cmd
+}
@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"chainguard.dev/melange/pkg/build"
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/checks"
+	"github.com/wolfi-dev/wolfictl/pkg/index"
+	"golang.org/x/exp/slices"
+)
+
+func CheckPublish() *cobra.Command {
+	var arch, repo string
+	var fetch indexFetchFlags
+	var jsonOutput, allowMissing bool
+	cmd := &cobra.Command{
+		Use:           "publish [configs...]",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Short:         "Show what building the given configs would add to a package repository",
+		Long: `Show what building the given configs would add to a package repository.
+
+Each config's version and epoch is compared to the newest version of the
+package in the repository's APKINDEX, and packages that would be new, get a
+version bump or get an epoch bump are listed. Configs that don't target the
+given architecture are ignored.
+
+If the repository has no APKINDEX for the architecture, the check fails,
+unless --allow-missing-index is passed to treat it as empty, e.g. for a new
+repository.
+
+If no configs are given, all configs in the current directory are compared.
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := fetch.options()
+			if err != nil {
+				return err
+			}
+
+			if allowMissing {
+				opts = append(opts, index.WithMissingAsEmpty())
+			}
+
+			changes, err := indexChanges(cmd.Context(), args, arch, repo, opts...)
+			if err != nil {
+				return err
+			}
+
+			var publish []checks.IndexChange
+			for _, c := range changes {
+				switch c.Kind {
+				case checks.IndexChangeNew, checks.IndexChangeVersionBump, checks.IndexChangeEpochBump:
+					publish = append(publish, c)
+				}
+			}
+
+			if jsonOutput {
+				if publish == nil {
+					publish = []checks.IndexChange{}
+				}
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(publish)
+			}
+
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "PACKAGE\tCHANGE\tINDEXED\tLOCAL")
+			for _, c := range publish {
+				indexed := c.Indexed
+				if indexed == "" {
+					indexed = "-"
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", c.Package, c.Kind, indexed, c.Local)
+			}
+			return tw.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&arch, "arch", "x86_64", "arch of the APKINDEX to compare against")
+	cmd.Flags().StringVar(&repo, "repo", "wolfi", "repo to compare against")
+	fetch.addFlags(cmd)
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "print the result as JSON")
+	cmd.Flags().BoolVar(&allowMissing, "allow-missing-index", false, "treat a repo with no APKINDEX for the arch as empty")
+	return cmd
+}
+
+// indexChanges compares the configs given in args, or all configs in the
+// current directory, to the APKINDEX for arch in repo, fetched with opts.
+// Configs that don't target arch are left out.
+func indexChanges(ctx context.Context, args []string, arch, repo string, opts ...index.Option) ([]checks.IndexChange, error) {
+	// Map a friendly string like "wolfi" to its repo URL.
+	if got, found := repos[repo]; found {
+		repo = got
+	}
+
+	cfgIndex, err := newConfigIndexFromArgs(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfgs []build.Configuration
+	for _, cfg := range cfgIndex.Configurations() { //nolint:gocritic // rangeValCopy rule not worth it here
+		ta := cfg.Package.TargetArchitecture
+		if len(ta) > 0 && !slices.Contains(ta, "all") && !slices.Contains(ta, arch) {
+			continue
+		}
+		cfgs = append(cfgs, cfg)
+	}
+
+	idx, err := index.Index(ctx, arch, repo, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get APKINDEX for arch %q: %w", arch, err)
+	}
+
+	return checks.CompareToIndex(cfgs, idx)
+}
@@ -22,9 +22,13 @@ func New() *cobra.Command {
 		GenerateIndex(),
 		cmdPod(),
 		cmdSVG(),
+		cmdCSV(),
+		cmdSPDX(),
 		cmdText(),
+		cmdRdeps(),
 		cmdMake(),
 		Check(),
+		Config(),
 		Lint(),
 		Update(),
 		VEX(),
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/spf13/cobra"
+)
+
+func Config() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "config",
+		SilenceErrors: true,
+		Short:         "Utilities for working with melange configs",
+	}
+
+	cmd.AddCommand(ConfigNormalizeArch())
+	cmd.AddCommand(ConfigPipelines())
+	cmd.AddCommand(ConfigSubpackages())
+	cmd.AddCommand(ConfigValidate())
+
+	return cmd
+}
+
+func ConfigValidate() *cobra.Command {
+	var schemaPath string
+	cmd := &cobra.Command{
+		Use:   "validate [configs...]",
+		Short: "Validate melange configs against a JSON schema",
+		Long: `Validate melange configs against a JSON schema.
+
+This check is independent of melange's own config parsing, so it can enforce a
+stricter policy than melange does. Each violation is reported with the config's
+file path and a JSON pointer to the offending value.
+
+If no configs are given, all configs in the current directory are validated.
+`,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schema, err := jsonschema.Compile(schemaPath)
+			if err != nil {
+				return fmt.Errorf("unable to compile JSON schema: %w", err)
+			}
+
+			index, err := newConfigIndexFromArgs(args...)
+			if err != nil {
+				return err
+			}
+
+			violations, err := index.Select().ValidateSchema(schema)
+			if err != nil {
+				return err
+			}
+
+			for _, v := range violations {
+				fmt.Println(v)
+			}
+
+			if len(violations) > 0 {
+				return fmt.Errorf("found %d schema violation(s)", len(violations))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&schemaPath, "schema", "", "path to the JSON schema to validate configs against")
+	cmd.MarkFlagRequired("schema") //nolint:errcheck
+
+	return cmd
+}
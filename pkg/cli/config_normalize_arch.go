@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"fmt"
+
+	"chainguard.dev/melange/pkg/build"
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/configs"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	targetArchFormAll      = "all"
+	targetArchFormExplicit = "explicit"
+)
+
+func ConfigNormalizeArch() *cobra.Command {
+	var form string
+	var archs []string
+	cmd := &cobra.Command{
+		Use:   "normalize-arch [configs...]",
+		Short: "Normalize package.target-architecture declarations",
+		Long: `Normalize the package.target-architecture declarations of melange configs to a
+canonical form.
+
+With --form=all (the default), a declaration listing exactly the architectures
+given by --archs is collapsed to "all". With --form=explicit, a declaration of
+"all" is expanded to the architectures given by --archs. Any other declaration
+is left alone, so running the command again makes no further changes.
+
+If no configs are given, all configs in the current directory are normalized.
+`,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if form != targetArchFormAll && form != targetArchFormExplicit {
+				return fmt.Errorf("invalid form %q, must be one of: %s, %s", form, targetArchFormAll, targetArchFormExplicit)
+			}
+			if len(archs) == 0 {
+				return fmt.Errorf("at least one architecture must be given via --archs")
+			}
+
+			index, err := newConfigIndexFromArgs(args...)
+			if err != nil {
+				return err
+			}
+
+			return index.Select().UpdateTargetArchitecture(func(cfg build.Configuration) ([]string, error) {
+				declared := cfg.Package.TargetArchitecture
+
+				normalized := configs.NormalizeTargetArchitecture(declared, archs, form == targetArchFormAll)
+				if slices.Equal(normalized, declared) {
+					return nil, configs.ErrSkip
+				}
+
+				fmt.Printf("%s: %v -> %v\n", cfg.Package.Name, declared, normalized)
+				return normalized, nil
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&form, "form", targetArchFormAll, fmt.Sprintf("canonical form to normalize to, one of: %s, %s", targetArchFormAll, targetArchFormExplicit))
+	cmd.Flags().StringSliceVar(&archs, "archs", []string{"x86_64", "aarch64"}, "the full set of architectures that \"all\" stands for")
+
+	return cmd
+}
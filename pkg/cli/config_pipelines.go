@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"chainguard.dev/melange/pkg/build"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+)
+
+func ConfigPipelines() *cobra.Command {
+	var pipelineDir, selectUses string
+	cmd := &cobra.Command{
+		Use:   "pipelines [configs...]",
+		Short: "List the pipelines referenced across melange configs",
+		Long: `List the pipelines referenced via "uses" across melange configs, with the
+number of references to each, including references from subpackage pipelines.
+
+Pipelines that melange can't find, either in --pipeline-dir or among its
+built-in pipelines, are flagged along with the packages that reference them,
+and the command fails. The same goes for pipelines that exist but themselves
+use a pipeline that can't be found, in which case the missing one is named.
+
+With --uses, only the names of the packages that reference the given pipeline
+are printed, e.g. to select the packages to rebuild after changing it.
+
+If no configs are given, all configs in the current directory are examined.
+`,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			index, err := newConfigIndexFromArgs(args...)
+			if err != nil {
+				return err
+			}
+
+			usages := make(map[string]*pipelineUsage)
+
+			cfgs := index.Configurations()
+			for i := range cfgs {
+				cfg := &cfgs[i]
+
+				refs := pipelineRefs(cfg.Pipeline)
+				for j := range cfg.Subpackages {
+					refs = append(refs, pipelineRefs(cfg.Subpackages[j].Pipeline)...)
+				}
+
+				for _, uses := range refs {
+					u, ok := usages[uses]
+					if !ok {
+						u = &pipelineUsage{
+							uses:    uses,
+							missing: missingPipeline(cfg, pipelineDir, uses),
+						}
+						usages[uses] = u
+					}
+
+					u.count++
+					if !slices.Contains(u.packages, cfg.Package.Name) {
+						u.packages = append(u.packages, cfg.Package.Name)
+					}
+				}
+			}
+
+			if selectUses != "" {
+				if u, ok := usages[selectUses]; ok {
+					sort.Strings(u.packages)
+					for _, name := range u.packages {
+						fmt.Println(name)
+					}
+				}
+				return nil
+			}
+
+			sorted := make([]*pipelineUsage, 0, len(usages))
+			for _, u := range usages {
+				sorted = append(sorted, u)
+			}
+			sort.Slice(sorted, func(i, j int) bool {
+				if sorted[i].count == sorted[j].count {
+					return sorted[i].uses < sorted[j].uses
+				}
+				return sorted[i].count > sorted[j].count
+			})
+
+			var missing []*pipelineUsage
+			for _, u := range sorted {
+				switch u.missing {
+				case "":
+					fmt.Printf("%6d %s\n", u.count, u.uses)
+					continue
+				case u.uses:
+					fmt.Printf("%6d %s (not found)\n", u.count, u.uses)
+				default:
+					fmt.Printf("%6d %s (uses missing pipeline %q)\n", u.count, u.uses, u.missing)
+				}
+				missing = append(missing, u)
+			}
+
+			if len(missing) == 0 {
+				return nil
+			}
+
+			fmt.Println()
+			for _, u := range missing {
+				sort.Strings(u.packages)
+				if u.missing == u.uses {
+					fmt.Printf("pipeline %q not found, referenced by: %s\n", u.uses, strings.Join(u.packages, ", "))
+					continue
+				}
+				fmt.Printf("pipeline %q not found, used by %q, referenced by: %s\n", u.missing, u.uses, strings.Join(u.packages, ", "))
+			}
+			return fmt.Errorf("found references to %d missing pipeline(s)", len(missing))
+		},
+	}
+
+	cmd.Flags().StringVar(&pipelineDir, "pipeline-dir", "", "directory used to extend the built-in pipelines")
+	cmd.Flags().StringVar(&selectUses, "uses", "", "only print the names of packages that reference this pipeline")
+
+	return cmd
+}
+
+type pipelineUsage struct {
+	uses     string
+	count    int
+	missing  string
+	packages []string
+}
+
+// pipelineRefs returns the "uses" value of every step in the given pipeline,
+// including nested steps.
+func pipelineRefs(pipeline []build.Pipeline) []string {
+	var refs []string
+	for i := range pipeline {
+		if uses := pipeline[i].Uses; uses != "" {
+			refs = append(refs, uses)
+		}
+		refs = append(refs, pipelineRefs(pipeline[i].Pipeline)...)
+	}
+	return refs
+}
+
+// missingPipeline returns the name of the pipeline that melange can't load when
+// loading the pipeline named by uses, looking in pipelineDir first and then at
+// its built-in pipelines. That's either uses itself or a pipeline it uses in
+// turn. If everything can be loaded, it returns "".
+func missingPipeline(cfg *build.Configuration, pipelineDir, uses string) string {
+	p := build.Pipeline{Uses: uses}
+	pctx := &build.PipelineContext{
+		Context: &build.Context{
+			Configuration: *cfg,
+			PipelineDir:   pipelineDir,
+		},
+		Package: &cfg.Package,
+	}
+
+	// Any other error (e.g. a missing required input) means the pipeline itself
+	// was found.
+	err := p.ApplyNeeds(pctx)
+	if !errors.Is(err, fs.ErrNotExist) {
+		return ""
+	}
+
+	// melange falls back to its built-in pipelines last, so the error is from
+	// looking up "pipelines/<name>.yaml" among them.
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return strings.TrimSuffix(strings.TrimPrefix(pathErr.Path, "pipelines/"), ".yaml")
+	}
+	return uses
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"chainguard.dev/melange/pkg/build"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineRefs(t *testing.T) {
+	refs := pipelineRefs([]build.Pipeline{
+		{Uses: "fetch"},
+		{Runs: "make"},
+		{Pipeline: []build.Pipeline{{Uses: "autoconf/configure"}, {Uses: "strip"}}},
+	})
+	assert.Equal(t, []string{"fetch", "autoconf/configure", "strip"}, refs)
+}
+
+func TestMissingPipeline(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "local"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "local", "ok.yaml"), []byte(`
+pipeline:
+  - uses: strip
+`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "local", "broken.yaml"), []byte(`
+pipeline:
+  - uses: strip
+  - uses: local/does-not-exist
+`), 0o644))
+
+	cfg := &build.Configuration{Package: build.Package{Name: "hello", Version: "1.0.0"}}
+
+	for _, tt := range []struct {
+		uses string
+		want string
+	}{
+		{uses: "strip", want: ""},
+		{uses: "local/ok", want: ""},
+		{uses: "typo", want: "typo"},
+		{uses: "local/broken", want: "local/does-not-exist"},
+	} {
+		t.Run(tt.uses, func(t *testing.T) {
+			assert.Equal(t, tt.want, missingPipeline(cfg, dir, tt.uses))
+		})
+	}
+}
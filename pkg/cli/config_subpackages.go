@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+func ConfigSubpackages() *cobra.Command {
+	var filter string
+	var jsonOutput bool
+	cmd := &cobra.Command{
+		Use:   "subpackages [configs...]",
+		Short: "List each package's subpackages",
+		Long: `List each package along with the subpackages declared in its melange config.
+Subpackages share the version of their origin package.
+
+If no configs are given, all configs in the current directory are listed.
+`,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := path.Match(filter, ""); err != nil {
+				return fmt.Errorf("invalid filter %q: %w", filter, err)
+			}
+
+			index, err := newConfigIndexFromArgs(args...)
+			if err != nil {
+				return err
+			}
+
+			var origins []originSubpackages
+
+			cfgs := index.Configurations()
+			for i := range cfgs {
+				cfg := &cfgs[i]
+
+				if filter != "" {
+					// The pattern was validated above.
+					if ok, _ := path.Match(filter, cfg.Package.Name); !ok {
+						continue
+					}
+				}
+
+				o := originSubpackages{
+					Name:        cfg.Package.Name,
+					Version:     fmt.Sprintf("%s-r%d", cfg.Package.Version, cfg.Package.Epoch),
+					Subpackages: []string{},
+				}
+				for j := range cfg.Subpackages {
+					o.Subpackages = append(o.Subpackages, cfg.Subpackages[j].Name)
+				}
+				origins = append(origins, o)
+			}
+
+			sort.Slice(origins, func(i, j int) bool {
+				return origins[i].Name < origins[j].Name
+			})
+
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(origins)
+			}
+
+			for _, o := range origins {
+				fmt.Printf("%s %s\n", o.Name, o.Version)
+				for _, s := range o.Subpackages {
+					fmt.Printf("  %s\n", s)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&filter, "filter", "", "only list packages whose name matches this glob pattern")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "print the result as JSON")
+
+	return cmd
+}
+
+type originSubpackages struct {
+	Name        string   `json:"name"`
+	Version     string   `json:"version"`
+	Subpackages []string `json:"subpackages"`
+}
@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dominikbraun/graph"
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/dag"
+)
+
+func cmdCSV() *cobra.Command {
+	var dir, nodesPath string
+	var showDependents bool
+	c := &cobra.Command{
+		Use:   "csv",
+		Short: "Generate a CSV edge list of the dependency graph",
+		Long: `
+Generate a CSV edge list of the dependency graph, one "source,target" row per
+edge, where the source package depends on the target package.
+
+  wolfictl csv > edges.csv
+
+Also write a CSV of the graph's nodes with their version metadata
+
+  wolfictl csv --nodes nodes.csv > edges.csv
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			g, err := dag.NewGraph(os.DirFS(dir))
+			if err != nil {
+				return err
+			}
+
+			g, err = selectSubgraph(g, args, showDependents)
+			if err != nil {
+				return err
+			}
+
+			if nodesPath != "" {
+				f, err := os.Create(nodesPath)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+
+				if err := nodesCSV(*g, f); err != nil {
+					return err
+				}
+			}
+
+			return edgesCSV(*g, os.Stdout)
+		},
+	}
+	c.Flags().StringVarP(&dir, "dir", "d", ".", "directory to search for melange configs")
+	c.Flags().BoolVarP(&showDependents, "show-dependents", "D", false, "show packages that depend on these packages, instead of these packages' dependencies")
+	c.Flags().StringVar(&nodesPath, "nodes", "", "if set, also write a CSV of nodes and their version metadata to this path")
+	return c
+}
+
+// sortedVertices returns the names of all vertices in the graph, sorted
+// alphabetically, along with the graph's adjacency map.
+func sortedVertices(g dag.Graph) ([]string, map[string]map[string]graph.Edge[string], error) {
+	adjacencyMap, err := g.Graph.AdjacencyMap()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	vertices := make([]string, 0, len(adjacencyMap))
+	for v := range adjacencyMap {
+		vertices = append(vertices, v)
+	}
+
+	// sort for deterministic output
+	sort.Strings(vertices)
+	return vertices, adjacencyMap, nil
+}
+
+func edgesCSV(g dag.Graph, w io.Writer) error {
+	vertices, adjacencyMap, err := sortedVertices(g)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"source", "target"}); err != nil {
+		return err
+	}
+	for _, v := range vertices {
+		deps := make([]string, 0, len(adjacencyMap[v]))
+		for dep := range adjacencyMap[v] {
+			deps = append(deps, dep)
+		}
+		sort.Strings(deps)
+
+		for _, dep := range deps {
+			if err := cw.Write([]string{v, dep}); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func nodesCSV(g dag.Graph, w io.Writer) error {
+	vertices, _, err := sortedVertices(g)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"name", "origin", "version", "epoch", "target-architecture"}); err != nil {
+		return err
+	}
+	for _, v := range vertices {
+		// Packages without a config in the graph are dependencies that are
+		// satisfied outside of this set of configs.
+		row := []string{v, "", "", "", ""}
+		if c := g.Config(v); c != nil {
+			row = []string{
+				v,
+				c.Package.Name,
+				c.Package.Version,
+				strconv.FormatUint(c.Package.Epoch, 10),
+				strings.Join(c.Package.TargetArchitecture, " "),
+			}
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
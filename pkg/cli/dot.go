@@ -9,6 +9,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/tmc/dot"
 	"github.com/wolfi-dev/wolfictl/pkg/dag"
+	"golang.org/x/exp/slices"
 )
 
 func cmdSVG() *cobra.Command {
@@ -25,46 +26,22 @@ Generate .dot output and pipe it to dot to generate an SVG
 Generate .dot output and pipe it to dot to generate a PNG
 
   wolfictl dot | dot -Tpng > graph.png
+
+Packages named as arguments are highlighted in the output.
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			g, err := dag.NewGraph(os.DirFS(dir), dir)
+			g, err := dag.NewGraph(os.DirFS(dir))
 			if err != nil {
 				return err
 			}
 
-			if len(args) == 0 {
-				if showDependents {
-					log.Print("warning: the 'show dependents' option has no effect without specifying one or more package names")
-				}
-			} else {
-				// ensure all packages exist in the graph
-				for _, arg := range args {
-					if _, err := g.Graph.Vertex(arg); err == graph.ErrVertexNotFound {
-						return fmt.Errorf("package %q not found in graph", arg)
-					}
-				}
-
-				// determine if we're examining dependencies or dependents
-				var subgraph *dag.Graph
-				if showDependents {
-					leaves := args
-					subgraph, err = g.SubgraphWithLeaves(leaves)
-					if err != nil {
-						return err
-					}
-				} else {
-					roots := args
-					subgraph, err = g.SubgraphWithRoots(roots)
-					if err != nil {
-						return err
-					}
-				}
-
-				g = subgraph
+			g, err = selectSubgraph(g, args, showDependents)
+			if err != nil {
+				return err
 			}
 
 			summarize(*g)
-			return viz(*g)
+			return viz(*g, args)
 		},
 	}
 	d.Flags().StringVarP(&dir, "dir", "d", ".", "directory to search for melange configs")
@@ -72,23 +49,70 @@ Generate .dot output and pipe it to dot to generate a PNG
 	return d
 }
 
+// selectSubgraph narrows g to the packages named in args and their
+// dependencies, or to the packages that depend on them if showDependents is
+// set. With no args, g is returned unchanged.
+func selectSubgraph(g *dag.Graph, args []string, showDependents bool) (*dag.Graph, error) {
+	if len(args) == 0 {
+		if showDependents {
+			log.Print("warning: the 'show dependents' option has no effect without specifying one or more package names")
+		}
+		return g, nil
+	}
+
+	// ensure all packages exist in the graph
+	for _, arg := range args {
+		if _, err := g.Graph.Vertex(arg); err == graph.ErrVertexNotFound {
+			return nil, fmt.Errorf("package %q not found in graph", arg)
+		}
+	}
+
+	// determine if we're examining dependencies or dependents
+	if showDependents {
+		leaves := args
+		return g.SubgraphWithLeaves(leaves)
+	}
+	roots := args
+	return g.SubgraphWithRoots(roots)
+}
+
 func summarize(g dag.Graph) {
 	log.Println("nodes:", g.Graph.Order())
 	log.Println("edges:", g.Graph.Size())
 }
 
-func viz(g dag.Graph) error {
+// viz prints g as graphviz .dot output, highlighting the named packages.
+func viz(g dag.Graph, highlight []string) error {
 	out := dot.NewGraph("images")
 	out.SetType(dot.DIGRAPH)
 
+	newNode := func(name string) (*dot.Node, error) {
+		n := dot.NewNode(name)
+		if slices.Contains(highlight, name) {
+			if err := n.Set("style", "filled"); err != nil {
+				return nil, err
+			}
+			if err := n.Set("fillcolor", "yellow"); err != nil {
+				return nil, err
+			}
+		}
+		return n, nil
+	}
+
 	nodes := g.Nodes()
 
 	for _, node := range nodes {
-		n := dot.NewNode(node)
+		n, err := newNode(node)
+		if err != nil {
+			return err
+		}
 		out.AddNode(n)
 
 		for _, dependency := range g.DependenciesOf(node) {
-			d := dot.NewNode(dependency)
+			d, err := newNode(dependency)
+			if err != nil {
+				return err
+			}
 			out.AddNode(d)
 			out.AddEdge(dot.NewEdge(n, d))
 		}
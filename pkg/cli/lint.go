@@ -2,9 +2,12 @@ package cli
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/wolfi-dev/wolfictl/pkg/lint"
+	"golang.org/x/exp/slices"
 )
 
 type lintOptions struct {
@@ -12,6 +15,7 @@ type lintOptions struct {
 	verbose   bool
 	list      bool
 	skipRules []string
+	required  []string
 }
 
 func Lint() *cobra.Command {
@@ -26,12 +30,16 @@ func Lint() *cobra.Command {
 			// args[0] can be used to get the path to the file to lint or `.` to lint the current directory
 			// what if given yaml is not Melange yaml?
 			o.args = args
+			if err := o.validateRequiredFields(); err != nil {
+				return err
+			}
 			return o.LintCmd()
 		},
 	}
 	cmd.Flags().BoolVarP(&o.verbose, "verbose", "v", false, "verbose output")
 	cmd.Flags().BoolVarP(&o.list, "list", "l", false, "prints the all of available rules and exits")
 	cmd.Flags().StringArrayVarP(&o.skipRules, "skip-rule", "", []string{}, "list of rules to skip")
+	cmd.Flags().StringArrayVarP(&o.required, "require-field", "", []string{}, "package metadata field every config must set, one of: "+strings.Join(lint.RequiredMetadataFields(), ", "))
 
 	cmd.AddCommand(LintYam())
 
@@ -59,6 +67,23 @@ func (o lintOptions) LintCmd() error {
 	return nil
 }
 
+// validateRequiredFields checks that every --require-field value names a
+// metadata field the linter knows how to check.
+func (o lintOptions) validateRequiredFields() error {
+	known := lint.RequiredMetadataFields()
+
+	var unknown []string
+	for _, field := range o.required {
+		if !slices.Contains(known, field) {
+			unknown = append(unknown, field)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown --require-field value(s) %s, must be one of: %s", strings.Join(unknown, ", "), strings.Join(known, ", "))
+	}
+	return nil
+}
+
 func (o lintOptions) makeLintOptions() []lint.Option {
 	if len(o.args) == 0 {
 		// Lint the current directory by default.
@@ -69,5 +94,6 @@ func (o lintOptions) makeLintOptions() []lint.Option {
 		lint.WithPath(o.args[0]),
 		lint.WithVerbose(o.verbose),
 		lint.WithSkipRules(o.skipRules),
+		lint.WithRequiredFields(o.required),
 	}
 }
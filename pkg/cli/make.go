@@ -19,7 +19,7 @@ func cmdMake() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			arch := types.ParseArchitecture(arch).ToAPK()
 
-			g, err := dag.NewGraph(os.DirFS(dir), dir)
+			g, err := dag.NewGraph(os.DirFS(dir))
 			if err != nil {
 				return err
 			}
@@ -74,7 +74,7 @@ func cmdPod() *cobra.Command {
 
 			targets := []string{"all"}
 			if len(args) > 0 {
-				g, err := dag.NewGraph(os.DirFS(dir), dir)
+				g, err := dag.NewGraph(os.DirFS(dir))
 				if err != nil {
 					return err
 				}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/dag"
+)
+
+func cmdRdeps() *cobra.Command {
+	var dir string
+	var transitive, jsonOutput bool
+	cmd := &cobra.Command{
+		Use:   "rdeps <package>",
+		Short: "Print the packages that depend on a package",
+		Long: `Print the packages that depend on the given package to build.
+
+By default only the immediate dependents are printed. With --transitive, every
+package that depends on the given package, directly or indirectly, is printed.
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pkg := args[0]
+
+			g, err := dag.NewGraph(os.DirFS(dir))
+			if err != nil {
+				return err
+			}
+
+			if _, err := g.Graph.Vertex(pkg); err != nil {
+				return fmt.Errorf("package %q not found in %q", pkg, dir)
+			}
+
+			var dependents []string
+			if transitive {
+				dependents, err = transitiveDependentsOf(g, pkg)
+				if err != nil {
+					return err
+				}
+			} else {
+				dependents = g.DependentsOf(pkg)
+			}
+
+			if jsonOutput {
+				// Keep the shape stable when there are no dependents.
+				if dependents == nil {
+					dependents = []string{}
+				}
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(struct {
+					Package    string   `json:"package"`
+					Dependents []string `json:"dependents"`
+				}{
+					Package:    pkg,
+					Dependents: dependents,
+				})
+			}
+
+			for _, d := range dependents {
+				fmt.Println(d)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&dir, "dir", "d", ".", "directory to search for melange configs")
+	cmd.Flags().BoolVar(&transitive, "transitive", false, "include packages that depend on the package indirectly")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "print the result as JSON")
+	return cmd
+}
+
+// transitiveDependentsOf returns the names of all packages in g that depend on
+// pkg, directly or indirectly, sorted alphabetically.
+func transitiveDependentsOf(g *dag.Graph, pkg string) ([]string, error) {
+	sub, err := g.SubgraphWithLeaves([]string{pkg})
+	if err != nil {
+		return nil, err
+	}
+
+	adjacencyMap, err := sub.Graph.AdjacencyMap()
+	if err != nil {
+		return nil, err
+	}
+
+	dependents := make([]string, 0, len(adjacencyMap))
+	for name := range adjacencyMap {
+		if name != pkg {
+			dependents = append(dependents, name)
+		}
+	}
+	sort.Strings(dependents)
+	return dependents, nil
+}
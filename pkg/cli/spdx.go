@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/wolfi-dev/wolfictl/pkg/dag"
+)
+
+func cmdSPDX() *cobra.Command {
+	var dir string
+	var showDependents bool
+	c := &cobra.Command{
+		Use:   "spdx",
+		Short: "Generate SPDX relationships for the dependency graph",
+		Long: `
+Generate the dependency graph as an SPDX 2.3 JSON document, with a package
+element for each package and DEPENDS_ON relationships between them.
+
+Package element IDs follow the scheme melange uses in the SBOMs it generates
+for each package, so the output can be merged with those SBOMs.
+
+  wolfictl spdx > relationships.spdx.json
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			g, err := dag.NewGraph(os.DirFS(dir))
+			if err != nil {
+				return err
+			}
+
+			g, err = selectSubgraph(g, args, showDependents)
+			if err != nil {
+				return err
+			}
+
+			return spdxRelationships(*g, os.Stdout)
+		},
+	}
+	c.Flags().StringVarP(&dir, "dir", "d", ".", "directory to search for melange configs")
+	c.Flags().BoolVarP(&showDependents, "show-dependents", "D", false, "show packages that depend on these packages, instead of these packages' dependencies")
+	return c
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	ID               string `json:"SPDXID"`
+	Name             string `json:"name"`
+	Version          string `json:"versionInfo,omitempty"`
+	DownloadLocation string `json:"downloadLocation"`
+	FilesAnalyzed    bool   `json:"filesAnalyzed"`
+}
+
+type spdxRelationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+}
+
+func spdxRelationships(g dag.Graph, w io.Writer) error {
+	vertices, adjacencyMap, err := sortedVertices(g)
+	if err != nil {
+		return err
+	}
+
+	const docName = "wolfictl-dependency-graph"
+	doc := struct {
+		Version       string             `json:"spdxVersion"`
+		DataLicense   string             `json:"dataLicense"`
+		ID            string             `json:"SPDXID"`
+		Name          string             `json:"name"`
+		Namespace     string             `json:"documentNamespace"`
+		CreationInfo  spdxCreationInfo   `json:"creationInfo"`
+		Packages      []spdxPackage      `json:"packages"`
+		Relationships []spdxRelationship `json:"relationships"`
+	}{
+		Version:     "SPDX-2.3",
+		DataLicense: "CC0-1.0",
+		ID:          "SPDXRef-DOCUMENT",
+		Name:        docName,
+		Namespace:   fmt.Sprintf("https://spdx.org/spdxdocs/%s-%s", docName, uuid.NewString()),
+		CreationInfo: spdxCreationInfo{
+			Created:  time.Now().UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: wolfictl"},
+		},
+		Packages:      []spdxPackage{},
+		Relationships: []spdxRelationship{},
+	}
+
+	ids := make(map[string]string, len(vertices))
+	idOwners := make(map[string]string, len(vertices))
+	for _, v := range vertices {
+		p := spdxPackage{
+			Name:             v,
+			DownloadLocation: "NOASSERTION",
+		}
+
+		// Packages without a config in the graph are dependencies that are
+		// satisfied outside of this set of configs, so their version is unknown.
+		id := v
+		if c := g.Config(v); c != nil && c.Package.Version != "PROVIDED" {
+			p.Version = fmt.Sprintf("%s-r%d", c.Package.Version, c.Package.Epoch)
+			id = fmt.Sprintf("%s-%s", v, p.Version)
+		}
+		p.ID = "SPDXRef-Package-" + spdxIdentifier(id)
+
+		// Different names can map to the same ID, e.g. "a/b" and "a-b".
+		if other, ok := idOwners[p.ID]; ok {
+			return fmt.Errorf("packages %q and %q have the same SPDX ID %q", other, v, p.ID)
+		}
+		idOwners[p.ID] = v
+
+		ids[v] = p.ID
+		doc.Packages = append(doc.Packages, p)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			Element: doc.ID,
+			Type:    "DESCRIBES",
+			Related: p.ID,
+		})
+	}
+
+	for _, v := range vertices {
+		deps := make([]string, 0, len(adjacencyMap[v]))
+		for dep := range adjacencyMap[v] {
+			deps = append(deps, dep)
+		}
+		sort.Strings(deps)
+
+		for _, dep := range deps {
+			doc.Relationships = append(doc.Relationships, spdxRelationship{
+				Element: ids[v],
+				Type:    "DEPENDS_ON",
+				Related: ids[dep],
+			})
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+var spdxInvalidIDCharsRe = regexp.MustCompile(`[^a-zA-Z0-9-.]+`)
+
+// spdxIdentifier makes s safe for use in an SPDX ID, the same way melange does
+// when generating package SBOMs.
+func spdxIdentifier(s string) string {
+	s = strings.ReplaceAll(s, ":", "-")
+	s = strings.ReplaceAll(s, "/", "-")
+	return spdxInvalidIDCharsRe.ReplaceAllStringFunc(s, func(m string) string {
+		var r strings.Builder
+		for i := 0; i < len(m); i++ {
+			fmt.Fprintf(&r, "C%d", m[i])
+		}
+		return r.String()
+	})
+}
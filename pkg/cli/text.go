@@ -3,11 +3,9 @@ package cli
 import (
 	"fmt"
 	"io"
-	"log"
 	"os"
 
 	"chainguard.dev/apko/pkg/build/types"
-	"github.com/dominikbraun/graph"
 	"github.com/spf13/cobra"
 	"github.com/wolfi-dev/wolfictl/pkg/dag"
 )
@@ -21,40 +19,14 @@ func cmdText() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			arch := types.ParseArchitecture(arch).ToAPK()
 
-			g, err := dag.NewGraph(os.DirFS(dir), dir)
+			g, err := dag.NewGraph(os.DirFS(dir))
 			if err != nil {
 				return err
 			}
 
-			if len(args) == 0 {
-				if showDependents {
-					log.Print("warning: the 'show dependents' option has no effect without specifying one or more package names")
-				}
-			} else {
-				// ensure all packages exist in the graph
-				for _, arg := range args {
-					if _, err := g.Graph.Vertex(arg); err == graph.ErrVertexNotFound {
-						return fmt.Errorf("package %q not found in graph", arg)
-					}
-				}
-
-				// determine if we're examining dependencies or dependents
-				var subgraph *dag.Graph
-				if showDependents {
-					leaves := args
-					subgraph, err = g.SubgraphWithLeaves(leaves)
-					if err != nil {
-						return err
-					}
-				} else {
-					roots := args
-					subgraph, err = g.SubgraphWithRoots(roots)
-					if err != nil {
-						return err
-					}
-				}
-
-				g = subgraph
+			g, err = selectSubgraph(g, args, showDependents)
+			if err != nil {
+				return err
 			}
 
 			return text(*g, arch, textType(t), os.Stdout)
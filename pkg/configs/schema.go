@@ -0,0 +1,107 @@
+package configs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// A SchemaViolation describes one way in which a configuration fails to conform
+// to a JSON schema.
+type SchemaViolation struct {
+	// Path is the path of the configuration file that has the violation.
+	Path string
+
+	// Location is a JSON pointer to the offending value within the
+	// configuration.
+	Location string
+
+	// Message describes the violation.
+	Message string
+}
+
+func (v SchemaViolation) String() string {
+	location := v.Location
+	if location == "" {
+		location = "/"
+	}
+	return fmt.Sprintf("%s: %s: %s", v.Path, location, v.Message)
+}
+
+// ValidateSchema validates the YAML of each configuration in the selection
+// against the given JSON schema. It returns all violations found across the
+// selection. An error is returned only if a configuration can't be validated at
+// all.
+func (s Selection) ValidateSchema(schema *jsonschema.Schema) ([]SchemaViolation, error) {
+	var violations []SchemaViolation
+
+	for _, e := range s.entries {
+		doc, err := jsonValueFromYAMLEntry(e)
+		if err != nil {
+			return nil, fmt.Errorf("unable to validate %q: %w", e.Path(), err)
+		}
+
+		err = schema.Validate(doc)
+		if err == nil {
+			continue
+		}
+
+		var validationErr *jsonschema.ValidationError
+		if !errors.As(err, &validationErr) {
+			return nil, fmt.Errorf("unable to validate %q: %w", e.Path(), err)
+		}
+
+		for _, leaf := range leafValidationErrors(validationErr) {
+			violations = append(violations, SchemaViolation{
+				Path:     e.Path(),
+				Location: leaf.InstanceLocation,
+				Message:  leaf.Message,
+			})
+		}
+	}
+
+	return violations, nil
+}
+
+// jsonValueFromYAMLEntry returns the entry's YAML document as the kind of value
+// that the jsonschema package expects, i.e. what encoding/json would produce
+// with UseNumber set.
+func jsonValueFromYAMLEntry(e Entry) (interface{}, error) {
+	var v interface{}
+	if err := e.YAMLRoot().Decode(&v); err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("unable to represent YAML as JSON: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// leafValidationErrors flattens the tree of validation errors down to the ones
+// that have no further causes, since those are the errors that point at a
+// specific problem in the document.
+func leafValidationErrors(ve *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(ve.Causes) == 0 {
+		return []*jsonschema.ValidationError{ve}
+	}
+
+	var leaves []*jsonschema.ValidationError
+	for _, cause := range ve.Causes {
+		leaves = append(leaves, leafValidationErrors(cause)...)
+	}
+	return leaves
+}
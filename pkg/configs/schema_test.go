@@ -0,0 +1,33 @@
+package configs
+
+import (
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+)
+
+func TestSelection_ValidateSchema(t *testing.T) {
+	schema, err := jsonschema.Compile("testdata/schema-1/schema.json")
+	require.NoError(t, err)
+
+	index, err := NewIndex(rwos.DirFS("testdata/schema-1/configs"))
+	require.NoError(t, err)
+
+	t.Run("valid config has no violations", func(t *testing.T) {
+		violations, err := index.Select().WherePackageName("valid").ValidateSchema(schema)
+		require.NoError(t, err)
+		assert.Empty(t, violations)
+	})
+
+	t.Run("reports missing required field", func(t *testing.T) {
+		violations, err := index.Select().WherePackageName("missing-description").ValidateSchema(schema)
+		require.NoError(t, err)
+		require.Len(t, violations, 1)
+		assert.Equal(t, "missing-description.yaml", violations[0].Path)
+		assert.Equal(t, "/package", violations[0].Location)
+		assert.Contains(t, violations[0].Message, "description")
+	})
+}
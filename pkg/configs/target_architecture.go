@@ -0,0 +1,33 @@
+package configs
+
+import "golang.org/x/exp/slices"
+
+// NormalizeTargetArchitecture returns the declared target architectures in
+// canonical form. If collapse is set, a declaration of exactly archs, in any
+// order, becomes "all"; otherwise "all" is expanded to archs. Other declarations
+// are returned unchanged, so normalizing a normalized declaration is a no-op.
+func NormalizeTargetArchitecture(declared, archs []string, collapse bool) []string {
+	isAll := len(declared) == 1 && declared[0] == "all"
+
+	if !collapse {
+		if isAll {
+			return slices.Clone(archs)
+		}
+		return declared
+	}
+
+	if isAll || len(declared) == 0 {
+		return declared
+	}
+	for _, a := range declared {
+		if !slices.Contains(archs, a) {
+			return declared
+		}
+	}
+	for _, a := range archs {
+		if !slices.Contains(declared, a) {
+			return declared
+		}
+	}
+	return []string{"all"}
+}
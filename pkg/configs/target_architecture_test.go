@@ -0,0 +1,87 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"chainguard.dev/melange/pkg/build"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rwos "github.com/wolfi-dev/wolfictl/pkg/configs/rwfs/os"
+	"golang.org/x/exp/slices"
+)
+
+func TestNormalizeTargetArchitecture(t *testing.T) {
+	archs := []string{"x86_64", "aarch64"}
+
+	for _, tt := range []struct {
+		name     string
+		declared []string
+		collapse bool
+		want     []string
+	}{
+		{name: "collapse full set", declared: []string{"x86_64", "aarch64"}, collapse: true, want: []string{"all"}},
+		{name: "collapse full set in other order", declared: []string{"aarch64", "x86_64"}, collapse: true, want: []string{"all"}},
+		{name: "collapse leaves subset", declared: []string{"x86_64"}, collapse: true, want: []string{"x86_64"}},
+		{name: "collapse leaves superset", declared: []string{"x86_64", "aarch64", "armv7"}, collapse: true, want: []string{"x86_64", "aarch64", "armv7"}},
+		{name: "collapse leaves all", declared: []string{"all"}, collapse: true, want: []string{"all"}},
+		{name: "collapse leaves undeclared", declared: nil, collapse: true, want: nil},
+		{name: "expand all", declared: []string{"all"}, collapse: false, want: []string{"x86_64", "aarch64"}},
+		{name: "expand leaves explicit", declared: []string{"aarch64"}, collapse: false, want: []string{"aarch64"}},
+		{name: "expand leaves undeclared", declared: nil, collapse: false, want: nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NormalizeTargetArchitecture(tt.declared, archs, tt.collapse)
+			assert.Equal(t, tt.want, got)
+
+			// Normalizing again changes nothing.
+			assert.Equal(t, got, NormalizeTargetArchitecture(got, archs, tt.collapse))
+		})
+	}
+}
+
+func TestSelection_UpdateTargetArchitecture(t *testing.T) {
+	archs := []string{"x86_64", "aarch64"}
+
+	for _, tt := range []struct {
+		file     string
+		collapse bool
+	}{
+		{file: "explicit.yaml", collapse: true},
+		{file: "all.yaml", collapse: false},
+	} {
+		t.Run(tt.file, func(t *testing.T) {
+			dir := t.TempDir()
+			in, err := os.ReadFile(filepath.Join("testdata", "target-architecture", tt.file))
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(filepath.Join(dir, tt.file), in, 0o644))
+
+			index, err := NewIndex(rwos.DirFS(dir))
+			require.NoError(t, err)
+
+			normalize := func(cfg build.Configuration) ([]string, error) {
+				declared := cfg.Package.TargetArchitecture
+				normalized := NormalizeTargetArchitecture(declared, archs, tt.collapse)
+				if slices.Equal(normalized, declared) {
+					return nil, ErrSkip
+				}
+				return normalized, nil
+			}
+
+			// Only the declaration changes; comments and key order are kept.
+			require.NoError(t, index.Select().UpdateTargetArchitecture(normalize))
+			want, err := os.ReadFile(filepath.Join("testdata", "target-architecture", "want", tt.file))
+			require.NoError(t, err)
+			got, err := os.ReadFile(filepath.Join(dir, tt.file))
+			require.NoError(t, err)
+			assert.Equal(t, string(want), string(got))
+
+			// A second run finds nothing to change and leaves the file alone.
+			require.NoError(t, index.Select().UpdateTargetArchitecture(normalize))
+			again, err := os.ReadFile(filepath.Join(dir, tt.file))
+			require.NoError(t, err)
+			assert.Equal(t, string(got), string(again))
+		})
+	}
+}
@@ -204,6 +204,54 @@ func (i *Index) newSubpackagesUpdater(updater UpdaterFunc[[]build.Subpackage]) u
 	return i.newYAMLUpdateFunc(yamlUpdateFunc)
 }
 
+// UpdateTargetArchitecture applies the given updater function to modify the
+// "package.target-architecture" declaration of each configuration in the
+// selection. Unlike UpdatePackage, only the declaration itself is re-encoded, so
+// the rest of the "package" section is left as it is.
+func (s Selection) UpdateTargetArchitecture(updater UpdaterFunc[[]string]) error {
+	u := s.index.newTargetArchitectureUpdater(updater)
+	for _, e := range s.entries {
+		err := s.index.update(e, u)
+		if err != nil {
+			if errors.Is(err, ErrSkip) {
+				continue
+			}
+
+			return fmt.Errorf("unable to update target architecture for %q: %w", e.Path(), err)
+		}
+	}
+
+	return nil
+}
+
+func (i *Index) newTargetArchitectureUpdater(updater UpdaterFunc[[]string]) updateFunc {
+	yamlUpdateFunc := func(cfg build.Configuration, node *yaml.Node) error {
+		updated, err := updater(cfg)
+		if err != nil {
+			return err
+		}
+
+		packageNode := yamlNodeForKey(node, "package")
+		archNode := yamlMapValueForKey(packageNode, "target-architecture")
+
+		encoded := yaml.Node{}
+		err = encoded.Encode(updated)
+		if err != nil {
+			return err
+		}
+
+		// Keep any comments attached to the declaration.
+		encoded.HeadComment = archNode.HeadComment
+		encoded.LineComment = archNode.LineComment
+		encoded.FootComment = archNode.FootComment
+		*archNode = encoded
+
+		return nil
+	}
+
+	return i.newYAMLUpdateFunc(yamlUpdateFunc)
+}
+
 func newYAMLSectionUpdateFunc[T any](
 	sectionName string,
 	updater UpdaterFunc[T],
@@ -51,18 +51,22 @@ func (i *Index) newYAMLUpdateFunc(updateYAML yamlUpdater) updateFunc {
 }
 
 func yamlNodeForKey(root *yaml.Node, key string) *yaml.Node {
-	rootMap := root.Content[0]
+	return yamlMapValueForKey(root.Content[0], key)
+}
 
-	iter := yit.FromNode(rootMap).ValuesForMap(yit.WithValue(key), yit.All)
-	advNode, ok := iter()
+// yamlMapValueForKey returns the value node for key in the given mapping node,
+// adding an empty mapping for key if it isn't there yet.
+func yamlMapValueForKey(mapNode *yaml.Node, key string) *yaml.Node {
+	iter := yit.FromNode(mapNode).ValuesForMap(yit.WithValue(key), yit.All)
+	valueNode, ok := iter()
 	if ok {
-		return advNode
+		return valueNode
 	}
 
 	mapKey := &yaml.Node{Value: key, Tag: "!!str", Kind: yaml.ScalarNode}
-	rootMap.Content = append(rootMap.Content, mapKey)
+	mapNode.Content = append(mapNode.Content, mapKey)
 	mapValue := &yaml.Node{Tag: "!!map", Kind: yaml.MappingNode}
-	rootMap.Content = append(rootMap.Content, mapValue)
+	mapNode.Content = append(mapNode.Content, mapValue)
 
 	return mapValue
 }
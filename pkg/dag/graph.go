@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io/fs"
 	"log"
-	"path/filepath"
 	"sort"
 	"strings"
 
@@ -26,10 +25,10 @@ func newGraph() graph.Graph[string, string] {
 
 // NewGraph returns a new Graph using Melange configuration discovered in the given directory.
 //
-// The input is any fs.FS filesystem implementation. Given a directory path, you can call NewGraph like this:
+// The input is any fs.FS filesystem implementation, and configs are read only through it. Given a directory path, you can call NewGraph like this:
 //
 // pkg.NewGraph(os.DirFS('path/to/directory'))
-func NewGraph(dirFS fs.FS, dirPath string) (*Graph, error) { //nolint:gocyclo
+func NewGraph(dirFS fs.FS) (*Graph, error) { //nolint:gocyclo
 	g := newGraph()
 
 	var packages []string
@@ -45,14 +44,7 @@ func NewGraph(dirFS fs.FS, dirPath string) (*Graph, error) { //nolint:gocyclo
 		}
 
 		if d.Type().IsRegular() && strings.HasSuffix(path, ".yaml") && !strings.HasPrefix(d.Name(), ".") {
-			f, err := dirFS.Open(path)
-			if err != nil {
-				return err
-			}
-			defer f.Close()
-
-			p := filepath.Join(dirPath, path)
-			c, err := build.ParseConfiguration(p)
+			c, err := build.ParseConfiguration(path, build.WithFS(dirFS))
 			if err != nil {
 				return err
 			}
@@ -69,10 +61,11 @@ func NewGraph(dirFS fs.FS, dirPath string) (*Graph, error) { //nolint:gocyclo
 			packages = append(packages, name)
 
 			for _, prov := range c.Package.Dependencies.Provides {
+				p := packageNameFromProvides(prov)
 				if _, exists := configs[p]; !exists {
 					configs[p] = build.Configuration{
 						Package: build.Package{
-							Name:        packageNameFromProvides(prov),
+							Name:        p,
 							Version:     "PROVIDED",
 							Description: fmt.Sprintf("PROVIDED BY %s", c.Package.Name),
 						},
@@ -386,3 +379,25 @@ func (g Graph) DependenciesOf(node string) []string {
 
 	return nil
 }
+
+// DependentsOf returns a slice of the names of the packages that directly depend on the given package, sorted alphabetically.
+func (g Graph) DependentsOf(node string) []string {
+	predecessorMap, err := g.Graph.PredecessorMap()
+	if err != nil {
+		return nil
+	}
+
+	var dependents []string
+
+	if deps, ok := predecessorMap[node]; ok {
+		for dep := range deps {
+			dependents = append(dependents, dep)
+		}
+
+		// sort for deterministic output
+		sort.Strings(dependents)
+		return dependents
+	}
+
+	return nil
+}
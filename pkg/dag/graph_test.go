@@ -3,6 +3,7 @@ package dag
 import (
 	"os"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -13,8 +14,35 @@ const testDir = "testdata"
 func TestNewGraph(t *testing.T) {
 	t.Run("does not panic", func(t *testing.T) {
 		assert.NotPanics(t, func() {
-			_, err := NewGraph(os.DirFS(testDir), testDir)
+			_, err := NewGraph(os.DirFS(testDir))
 			require.NoError(t, err)
 		})
 	})
+
+	t.Run("reads configs from any fs.FS", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"hello.yaml": &fstest.MapFile{Data: []byte(`package:
+  name: hello
+  version: 1.0.0
+  epoch: 0
+environment:
+  contents:
+    packages:
+      - build-base
+`)},
+		}
+
+		g, err := NewGraph(fsys)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"hello"}, g.Nodes())
+		assert.Equal(t, []string{"build-base"}, g.DependenciesOf("hello"))
+	})
+}
+
+func TestGraph_DependentsOf(t *testing.T) {
+	g, err := NewGraph(os.DirFS(testDir))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"busybox"}, g.DependentsOf("build-base"))
+	assert.Empty(t, g.DependentsOf("busybox"))
 }
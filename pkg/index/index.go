@@ -1,40 +1,222 @@
 package index
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"log"
+	"math/rand"
 	"net/http"
+	"net/url"
 	"os"
-	"strings"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
 
 	"gitlab.alpinelinux.org/alpine/go/repository"
 )
 
-func Index(arch, repo string) (*repository.ApkIndex, error) {
-	var rc io.ReadCloser
-	if strings.HasPrefix(repo, "http://") || strings.HasPrefix(repo, "https://") {
-		url := fmt.Sprintf("%s/%s/APKINDEX.tar.gz", repo, arch)
-		resp, err := http.Get(url) //nolint:gosec
+type options struct {
+	indexFile      string
+	header         http.Header
+	retries        int
+	missingAsEmpty bool
+	baseDelay      time.Duration
+}
+
+// An Option configures how an index is fetched.
+type Option func(*options)
+
+// WithIndexFile sets the file name of the index within the repo's arch
+// directory. The default is APKINDEX.tar.gz.
+func WithIndexFile(name string) Option {
+	return func(o *options) {
+		o.indexFile = name
+	}
+}
+
+// WithHTTPHeader sets headers to send when fetching an index over HTTP.
+func WithHTTPHeader(header http.Header) Option {
+	return func(o *options) {
+		o.header = header
+	}
+}
+
+// WithRetries sets how many times a failed request for a remote index is
+// retried. Network errors, 429s and 5xx responses are retried, with exponential
+// backoff and jitter, honoring any Retry-After header. The default is 3.
+func WithRetries(retries int) Option {
+	return func(o *options) {
+		o.retries = retries
+	}
+}
+
+// WithMissingAsEmpty makes a remote repo that has no index for the arch (a 404)
+// yield an empty index, as for a new repo, rather than an error.
+func WithMissingAsEmpty() Option {
+	return func(o *options) {
+		o.missingAsEmpty = true
+	}
+}
+
+// schemeRe matches the "scheme://" prefix of a URL.
+var schemeRe = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*://`)
+
+// Index returns the APKINDEX for arch in repo. The repo can be an http(s) URL, a
+// file:// URL, or a local path.
+func Index(ctx context.Context, arch, repo string, opts ...Option) (*repository.ApkIndex, error) {
+	o := &options{indexFile: "APKINDEX.tar.gz", retries: 3, baseDelay: time.Second}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	// Bare paths aren't parsed as URLs, since they can contain characters, like
+	// "%", that aren't valid in one.
+	if !schemeRe.MatchString(repo) {
+		return localIndex(arch, repo, o.indexFile)
+	}
+
+	u, err := url.Parse(repo)
+	if err != nil {
+		return nil, fmt.Errorf("parsing repo %q: %w", repo, err)
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		return remoteIndex(ctx, arch, repo, o)
+	case "file":
+		if u.Host != "" && u.Host != "localhost" {
+			return nil, fmt.Errorf("file URL %q must have an empty or localhost host", repo)
+		}
+		return localIndex(arch, u.Path, o.indexFile)
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q in repo %q", u.Scheme, repo)
+	}
+}
+
+// remoteIndex fetches the APKINDEX for arch from the repo at repoURL.
+func remoteIndex(ctx context.Context, arch, repoURL string, o *options) (*repository.ApkIndex, error) {
+	indexURL := fmt.Sprintf("%s/%s/%s", repoURL, arch, o.indexFile)
+	resp, err := get(ctx, indexURL, o)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound && o.missingAsEmpty {
+		// There's nothing published for this arch yet.
+		return &repository.ApkIndex{}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		b, err := io.ReadAll(resp.Body)
 		if err != nil {
 			return nil, err
 		}
-		defer resp.Body.Close()
-		if resp.StatusCode != http.StatusOK {
-			b, err := io.ReadAll(resp.Body)
-			if err != nil {
+		return nil, fmt.Errorf("GET %s (%d): %s", indexURL, resp.StatusCode, b)
+	}
+
+	return repository.IndexFromArchive(resp.Body)
+}
+
+// localIndex reads the APKINDEX at p. If p is a directory, it's treated as a
+// local repository, with the index at <p>/<arch>/<indexFile>.
+func localIndex(arch, p, indexFile string) (*repository.ApkIndex, error) {
+	fi, err := os.Stat(p)
+	if err != nil {
+		return nil, fmt.Errorf("opening %q: %w", p, err)
+	}
+	if fi.IsDir() {
+		p = filepath.Join(p, arch, indexFile)
+	}
+
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, fmt.Errorf("opening %q: %w", p, err)
+	}
+	defer f.Close()
+
+	return repository.IndexFromArchive(f)
+}
+
+// get makes a GET request for indexURL, retrying as configured by o.
+func get(ctx context.Context, indexURL string, o *options) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, indexURL, http.NoBody)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range o.header {
+			req.Header[k] = v
+		}
+
+		var wait time.Duration
+		resp, err := http.DefaultClient.Do(req)
+		switch {
+		case err != nil:
+			if ctx.Err() != nil || attempt >= o.retries {
 				return nil, err
 			}
-			return nil, fmt.Errorf("GET %s (%d): %s", url, resp.StatusCode, b)
+			wait = backoff(o.baseDelay, attempt)
+			log.Printf("GET %s failed, retrying in %s: %v", indexURL, wait, err)
+		case isRetryable(resp.StatusCode) && attempt < o.retries:
+			wait = retryAfter(resp)
+			if wait == 0 {
+				wait = backoff(o.baseDelay, attempt)
+			}
+			resp.Body.Close()
+			log.Printf("GET %s (%d), retrying in %s", indexURL, resp.StatusCode, wait)
+		default:
+			return resp, nil
 		}
-		rc = resp.Body
-	} else {
-		f, err := os.Open(repo)
-		if err != nil {
-			return nil, fmt.Errorf("opening %q: %w", repo, err)
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
 		}
-		defer f.Close()
-		rc = f
 	}
+}
+
+func isRetryable(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
 
-	return repository.IndexFromArchive(rc)
+// maxBackoff caps the delay between retries, before jitter.
+const maxBackoff = 30 * time.Second
+
+// backoff returns the delay before the given retry attempt: the base delay
+// doubled for each prior attempt, up to maxBackoff, plus up to 50% jitter.
+func backoff(base time.Duration, attempt int) time.Duration {
+	d := base
+	for i := 0; i < attempt && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	return d + time.Duration(rand.Int63n(int64(d/2)+1)) //nolint:gosec // jitter doesn't need a secure source
+}
+
+// retryAfter returns the delay requested by a 429 or 503 response's Retry-After
+// header, or zero if there isn't one.
+func retryAfter(resp *http.Response) time.Duration {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0
+	}
+
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
 }
@@ -0,0 +1,183 @@
+package index
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.alpinelinux.org/alpine/go/repository"
+)
+
+func TestIndex_Local(t *testing.T) {
+	// "%" isn't valid in a URL, but it's fine in a path.
+	dir := filepath.Join(t.TempDir(), "100%")
+	indexPath := filepath.Join(dir, "x86_64", "APKINDEX.tar.gz")
+	require.NoError(t, os.MkdirAll(filepath.Dir(indexPath), 0o755))
+
+	r, err := repository.ArchiveFromIndex(&repository.ApkIndex{
+		Packages: []*repository.Package{{Name: "hello", Version: "1.0.0-r0"}},
+	})
+	require.NoError(t, err)
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(indexPath, b, 0o644))
+
+	for _, tt := range []struct {
+		name string
+		repo string
+	}{
+		{name: "index file", repo: indexPath},
+		{name: "repo directory", repo: dir},
+		{name: "file URL", repo: (&url.URL{Scheme: "file", Path: dir}).String()},
+		{name: "file URL with localhost", repo: (&url.URL{Scheme: "file", Host: "localhost", Path: indexPath}).String()},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			idx, err := Index(context.Background(), "x86_64", tt.repo)
+			require.NoError(t, err)
+			require.Len(t, idx.Packages, 1)
+			assert.Equal(t, "hello", idx.Packages[0].Name)
+		})
+	}
+
+	t.Run("file URL with another host", func(t *testing.T) {
+		_, err := Index(context.Background(), "x86_64", "file://./repo")
+		assert.ErrorContains(t, err, "must have an empty or localhost host")
+	})
+
+	t.Run("unsupported scheme", func(t *testing.T) {
+		_, err := Index(context.Background(), "x86_64", "gs://bucket/os")
+		assert.ErrorContains(t, err, "unsupported scheme")
+	})
+
+	t.Run("custom index name", func(t *testing.T) {
+		require.NoError(t, os.Rename(indexPath, filepath.Join(dir, "x86_64", "APKINDEX-custom.tar.gz")))
+
+		idx, err := Index(context.Background(), "x86_64", dir, WithIndexFile("APKINDEX-custom.tar.gz"))
+		require.NoError(t, err)
+		require.Len(t, idx.Packages, 1)
+	})
+}
+
+func TestIndex_Remote(t *testing.T) {
+	r, err := repository.ArchiveFromIndex(&repository.ApkIndex{
+		Packages: []*repository.Package{{Name: "hello", Version: "1.0.0-r0"}},
+	})
+	require.NoError(t, err)
+	archive, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	fastRetries := func(o *options) { o.baseDelay = time.Millisecond }
+
+	t.Run("retries server errors", func(t *testing.T) {
+		requests := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if requests < 3 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.Write(archive) //nolint:errcheck
+		}))
+		defer srv.Close()
+
+		idx, err := Index(context.Background(), "x86_64", srv.URL, WithRetries(3), fastRetries)
+		require.NoError(t, err)
+		require.Len(t, idx.Packages, 1)
+		assert.Equal(t, 3, requests)
+	})
+
+	t.Run("gives up after retries", func(t *testing.T) {
+		requests := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+
+		_, err := Index(context.Background(), "x86_64", srv.URL, WithRetries(2), fastRetries)
+		require.Error(t, err)
+		assert.Equal(t, 3, requests)
+	})
+
+	t.Run("does not retry client errors", func(t *testing.T) {
+		requests := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer srv.Close()
+
+		_, err := Index(context.Background(), "x86_64", srv.URL, WithRetries(3), fastRetries)
+		require.Error(t, err)
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("sends headers", func(t *testing.T) {
+		var got http.Header
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.Header.Clone()
+			w.Write(archive) //nolint:errcheck
+		}))
+		defer srv.Close()
+
+		header := make(http.Header)
+		header.Add("X-Route", "mirror")
+		_, err := Index(context.Background(), "x86_64", srv.URL, WithHTTPHeader(header))
+		require.NoError(t, err)
+		assert.Equal(t, "mirror", got.Get("X-Route"))
+	})
+
+	t.Run("missing index is an error", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		defer srv.Close()
+
+		_, err := Index(context.Background(), "x86_64", srv.URL)
+		assert.ErrorContains(t, err, "(404)")
+	})
+
+	t.Run("missing index can be empty", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		defer srv.Close()
+
+		idx, err := Index(context.Background(), "x86_64", srv.URL, WithMissingAsEmpty())
+		require.NoError(t, err)
+		assert.Empty(t, idx.Packages)
+	})
+
+	t.Run("retries by default", func(t *testing.T) {
+		requests := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer srv.Close()
+
+		_, err := Index(context.Background(), "x86_64", srv.URL, fastRetries)
+		require.Error(t, err)
+		assert.Equal(t, 4, requests)
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	for _, tt := range []struct {
+		attempt int
+		min     time.Duration
+	}{
+		{attempt: 0, min: time.Second},
+		{attempt: 2, min: 4 * time.Second},
+		{attempt: 5, min: maxBackoff},
+		{attempt: 100, min: maxBackoff},
+	} {
+		d := backoff(time.Second, tt.attempt)
+		assert.GreaterOrEqual(t, d, tt.min, "attempt %d", tt.attempt)
+		assert.LessOrEqual(t, d, tt.min+tt.min/2, "attempt %d", tt.attempt)
+	}
+}
@@ -148,6 +148,13 @@ func (l *Linter) checkIfMakefileExists() ConditionFunc {
 	}
 }
 
+// checkIfRequiredFieldsSet returns a ConditionFunc that checks if any required metadata fields were given.
+func (l *Linter) checkIfRequiredFieldsSet() ConditionFunc {
+	return func() bool {
+		return len(l.options.RequiredFields) > 0
+	}
+}
+
 // readMakefile reads the Makefile from the file.
 func (l *Linter) readMakefile() error {
 	cmd := exec.Command("make", "-C", l.options.Path, "list") //nolint: gosec
@@ -11,8 +11,8 @@ func newTestLinterWithDir(path string) *Linter {
 	return New(WithPath(filepath.Join("testdata", path)))
 }
 
-func newTestLinterWithFile(path string) *Linter {
-	return New(WithPath(filepath.Join("testdata/files/", path)))
+func newTestLinterWithFile(path string, opts ...Option) *Linter {
+	return New(append([]Option{WithPath(filepath.Join("testdata/files/", path))}, opts...)...)
 }
 
 func TestLinter_Dir(t *testing.T) {
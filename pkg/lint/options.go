@@ -10,6 +10,9 @@ type Options struct {
 
 	// Skip rules removes the given slice of rules to be checked
 	SkipRules []string
+
+	// RequiredFields is the list of package metadata fields every config must set
+	RequiredFields []string
 }
 
 // Option represents a linter option.
@@ -35,3 +38,10 @@ func WithSkipRules(skipRules []string) Option {
 		o.SkipRules = skipRules
 	}
 }
+
+// WithRequiredFields sets the package metadata fields every config must set.
+func WithRequiredFields(fields []string) Option {
+	return func(o *Options) {
+		o.RequiredFields = fields
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/url"
 	"os"
 	"regexp"
+	"sort"
 	"strings"
 
 	"chainguard.dev/melange/pkg/renovate"
@@ -30,11 +31,39 @@ var (
 		"https://packages.wolfi.dev/os/wolfi-signing.rsa.pub",
 	}
 
+	// requiredMetadataChecks reports whether a package sets the given metadata field.
+	requiredMetadataChecks = map[string]func(build.Package) bool{
+		"description": func(p build.Package) bool { return p.Description != "" },
+		"url":         func(p build.Package) bool { return p.URL != "" },
+		"license": func(p build.Package) bool {
+			if len(p.Copyright) == 0 {
+				return false
+			}
+			for _, c := range p.Copyright {
+				if c.License == "" {
+					return false
+				}
+			}
+			return true
+		},
+	}
+
 	// versionRegex how to parse versions.
 	// see https://github.com/alpinelinux/apk-tools/blob/50ab589e9a5a84592ee4c0ac5a49506bb6c552fc/src/version.c#
 	versionRegex = regexp.MustCompile(`^([0-9]+)((\.[0-9]+)*)([a-z]?)((_alpha|_beta|_pre|_rc)([0-9]*))?((_cvs|_svn|_git|_hg|_p)([0-9]*))?((-r)([0-9]+))?$`)
 )
 
+// RequiredMetadataFields returns the names of the package metadata fields that
+// can be required with WithRequiredFields, sorted alphabetically.
+func RequiredMetadataFields() []string {
+	fields := make([]string, 0, len(requiredMetadataChecks))
+	for field := range requiredMetadataChecks {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
 func init() { versionRegex.Longest() }
 
 // AllRules is a list of all available rules to evaluate.
@@ -292,6 +321,30 @@ var AllRules = func(l *Linter) Rules { //nolint:gocyclo
 				return nil
 			},
 		},
+		{
+			Name:        "contains-required-metadata",
+			Description: "every package should set the required metadata fields",
+			Severity:    SeverityError,
+			LintFunc: func(config build.Configuration) error {
+				var missing []string
+				for _, field := range l.options.RequiredFields {
+					check, ok := requiredMetadataChecks[field]
+					if !ok {
+						return fmt.Errorf("unknown required metadata field %s", field)
+					}
+					if !check(config.Package) {
+						missing = append(missing, field)
+					}
+				}
+				if len(missing) > 0 {
+					return fmt.Errorf("missing required metadata: %s", strings.Join(missing, ", "))
+				}
+				return nil
+			},
+			ConditionFuncs: []ConditionFunc{
+				l.checkIfRequiredFieldsSet(),
+			},
+		},
 	}
 }
 
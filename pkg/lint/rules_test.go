@@ -10,10 +10,11 @@ import (
 
 func TestLinter_Rules(t *testing.T) {
 	tests := []struct {
-		name    string
-		file    string
-		want    EvalResult
-		wantErr bool
+		name     string
+		file     string
+		required []string
+		want     EvalResult
+		wantErr  bool
 	}{
 		{
 			file: "missing-copyright.yaml",
@@ -222,11 +223,28 @@ func TestLinter_Rules(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			file:     "missing-metadata.yaml",
+			required: []string{"description", "license", "url"},
+			want: EvalResult{
+				File: "missing-metadata",
+				Errors: EvalRuleErrors{
+					{
+						Rule: Rule{
+							Name:     "contains-required-metadata",
+							Severity: SeverityError,
+						},
+						Error: fmt.Errorf("[contains-required-metadata]: missing required metadata: description, url (ERROR)"),
+					},
+				},
+			},
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.file, func(t *testing.T) {
-			l := newTestLinterWithFile(tt.file)
+			l := newTestLinterWithFile(tt.file, WithRequiredFields(tt.required))
 			got, err := l.Lint()
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Lint() error = %v, wantErr %v", err, tt.wantErr)